@@ -0,0 +1,59 @@
+package directv
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestGetLocationsContextCancellation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		w.Write([]byte(`{"locations":[{"clientAddr":"0","locationName":"Living Room"}]}`))
+	}))
+	defer server.Close()
+
+	stb := newTestSTBForServer(t, server)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Millisecond)
+	defer cancel()
+
+	_, err := stb.GetLocationsContext(ctx)
+	if err == nil {
+		t.Error("Expected a context deadline exceeded error, got nil")
+	}
+}
+
+// TestRetryBackoffRespectsContext confirms that a request configured with
+// WithRetry bails out as soon as ctx is cancelled, instead of sleeping out
+// the remainder of the retry backoff.
+func TestRetryBackoffRespectsContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hj, ok := w.(http.Hijacker)
+		if !ok {
+			t.Fatal("ResponseWriter does not support hijacking")
+		}
+		conn, _, err := hj.Hijack()
+		if err != nil {
+			t.Fatal(err)
+		}
+		conn.Close()
+	}))
+	defer server.Close()
+
+	stb := newTestSTBForServer(t, server, WithRetry(5, time.Hour))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err := stb.GetLocationsContext(ctx)
+	if err == nil {
+		t.Fatal("Expected an error from the cancelled retry, got nil")
+	}
+	if elapsed := time.Since(start); elapsed > 1*time.Second {
+		t.Error("Expected the retry loop to return shortly after ctx was cancelled, took", elapsed)
+	}
+}
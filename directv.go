@@ -1,6 +1,7 @@
 package directv
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -8,12 +9,21 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
+	"time"
 )
 
 // SetTopBox is the primary object to use in the directv library.
 type SetTopBox struct {
 	IPAddress string
 	Port      int
+
+	scheme       string
+	httpClient   *http.Client
+	username     string
+	password     string
+	retryCount   int
+	retryBackoff time.Duration
+	logger       Logger
 }
 
 // Location represents a single Set Top Box location name.
@@ -197,15 +207,33 @@ const (
 	CommandOpenUserChannelMT   = "FA9F"
 )
 
-// NewSetTopBox initialized a new SetTopBox struct with the supplied ip address
-// and default port.
-func NewSetTopBox(ip string) *SetTopBox {
-	return &SetTopBox{ip, 8080}
+// NewSetTopBox initializes a new SetTopBox struct with the supplied ip
+// address and default port. Use the With* options to override the port,
+// inject an *http.Client, set a timeout, configure basic auth, retries, or
+// attach a logger.
+func NewSetTopBox(ip string, opts ...Option) *SetTopBox {
+	stb := &SetTopBox{
+		IPAddress:  ip,
+		Port:       defaultPort,
+		scheme:     defaultScheme,
+		httpClient: &http.Client{Timeout: defaultTimeout},
+	}
+
+	for _, opt := range opts {
+		opt(stb)
+	}
+
+	return stb
 }
 
 // IsConnected returns true if the current SetTopBox object can talk to the DirecTV Set Top Box.
 func (stb *SetTopBox) IsConnected() (bool, error) {
-	locations, err := stb.GetLocations()
+	return stb.IsConnectedContext(context.Background())
+}
+
+// IsConnectedContext is the context-aware variant of IsConnected.
+func (stb *SetTopBox) IsConnectedContext(ctx context.Context) (bool, error) {
+	locations, err := stb.GetLocationsContext(ctx)
 	if err != nil {
 		return false, err
 	}
@@ -218,8 +246,13 @@ func (stb *SetTopBox) IsConnected() (bool, error) {
 
 // GetLocations calls /info/getLocations and returns the returned locations.
 func (stb *SetTopBox) GetLocations() ([]Location, error) {
+	return stb.GetLocationsContext(context.Background())
+}
+
+// GetLocationsContext is the context-aware variant of GetLocations.
+func (stb *SetTopBox) GetLocationsContext(ctx context.Context) ([]Location, error) {
 	var locationsRes getLocationsResponse
-	_, err := stb.request("/info/getLocations", nil, &locationsRes)
+	_, err := stb.request(ctx, "/info/getLocations", nil, &locationsRes)
 	if err != nil {
 		if locationsRes.Status.Message != "" {
 			err = errors.New(locationsRes.Status.Message)
@@ -232,12 +265,17 @@ func (stb *SetTopBox) GetLocations() ([]Location, error) {
 
 // GetSerialNum calls /info/getSerialNum and returns the STB Serial Number
 func (stb *SetTopBox) GetSerialNum(clientAddr string) (string, error) {
+	return stb.GetSerialNumContext(context.Background(), clientAddr)
+}
+
+// GetSerialNumContext is the context-aware variant of GetSerialNum.
+func (stb *SetTopBox) GetSerialNumContext(ctx context.Context, clientAddr string) (string, error) {
 	var serialNumResponse getSerialNumResponse
 	params := map[string]string{}
 	if len(clientAddr) != 0 {
 		params["clientAddr"] = clientAddr
 	}
-	_, err := stb.request("/info/getSerialNum", params, &serialNumResponse)
+	_, err := stb.request(ctx, "/info/getSerialNum", params, &serialNumResponse)
 	if err != nil {
 		if serialNumResponse.Status.Message != "" {
 			err = errors.New(serialNumResponse.Status.Message)
@@ -250,9 +288,14 @@ func (stb *SetTopBox) GetSerialNum(clientAddr string) (string, error) {
 
 // GetVersion returns the version information, including time, from the SetTopBox
 func (stb *SetTopBox) GetVersion() (Version, error) {
+	return stb.GetVersionContext(context.Background())
+}
+
+// GetVersionContext is the context-aware variant of GetVersion.
+func (stb *SetTopBox) GetVersionContext(ctx context.Context) (Version, error) {
 	var versionResponse getVersionResponse
 	var version Version
-	_, err := stb.request("/info/getVersion", nil, &versionResponse)
+	_, err := stb.request(ctx, "/info/getVersion", nil, &versionResponse)
 	if err != nil {
 		if versionResponse.Status.Message != "" {
 			err = errors.New(versionResponse.Status.Message)
@@ -273,12 +316,17 @@ func (stb *SetTopBox) GetVersion() (Version, error) {
 
 // GetMode calls /info/mode and returns the mode the STB is operating in.
 func (stb *SetTopBox) GetMode(clientAddr string) (int, error) {
+	return stb.GetModeContext(context.Background(), clientAddr)
+}
+
+// GetModeContext is the context-aware variant of GetMode.
+func (stb *SetTopBox) GetModeContext(ctx context.Context, clientAddr string) (int, error) {
 	var modeResponse modeResponse
 	params := map[string]string{}
 	if len(clientAddr) != 0 {
 		params["clientAddr"] = clientAddr
 	}
-	_, err := stb.request("/info/mode", params, &modeResponse)
+	_, err := stb.request(ctx, "/info/mode", params, &modeResponse)
 	if err != nil {
 		if modeResponse.Status.Message != "" {
 			err = errors.New(modeResponse.Status.Message)
@@ -291,6 +339,11 @@ func (stb *SetTopBox) GetMode(clientAddr string) (int, error) {
 
 // ProcessKey sends a remote key press to the STB.
 func (stb *SetTopBox) ProcessKey(key string, hold string, clientAddr string) error {
+	return stb.ProcessKeyContext(context.Background(), key, hold, clientAddr)
+}
+
+// ProcessKeyContext is the context-aware variant of ProcessKey.
+func (stb *SetTopBox) ProcessKeyContext(ctx context.Context, key string, hold string, clientAddr string) error {
 	var processKeyResponse processKeyResponse
 	params := map[string]string{
 		"key": key,
@@ -301,7 +354,7 @@ func (stb *SetTopBox) ProcessKey(key string, hold string, clientAddr string) err
 	if len(clientAddr) != 0 {
 		params["clientAddr"] = clientAddr
 	}
-	_, err := stb.request("/remote/processKey", params, &processKeyResponse)
+	_, err := stb.request(ctx, "/remote/processKey", params, &processKeyResponse)
 	if err != nil {
 		if processKeyResponse.Status.Message != "" {
 			err = errors.New(processKeyResponse.Status.Message)
@@ -314,10 +367,15 @@ func (stb *SetTopBox) ProcessKey(key string, hold string, clientAddr string) err
 
 // ProcessCommand sends a serial command (hex value) to the Set Top Box.
 func (stb *SetTopBox) ProcessCommand(cmd string) (CommandResponse, error) {
+	return stb.ProcessCommandContext(context.Background(), cmd)
+}
+
+// ProcessCommandContext is the context-aware variant of ProcessCommand.
+func (stb *SetTopBox) ProcessCommandContext(ctx context.Context, cmd string) (CommandResponse, error) {
 	var response processCommandResponse
 	var commandResponse CommandResponse
 	params := map[string]string{"cmd": cmd}
-	_, err := stb.request("/serial/processCommand", params, &response)
+	_, err := stb.request(ctx, "/serial/processCommand", params, &response)
 	if err != nil {
 		if response.Status.Message != "" {
 			err = errors.New(response.Status.Message)
@@ -337,6 +395,11 @@ func (stb *SetTopBox) ProcessCommand(cmd string) (CommandResponse, error) {
 
 // GetProgInfo returns information about the program on the specifed channel.
 func (stb *SetTopBox) GetProgInfo(channelMajor int, channelMinor int, time int64, clientAddr string) (ProgramStatusResponse, error) {
+	return stb.GetProgInfoContext(context.Background(), channelMajor, channelMinor, time, clientAddr)
+}
+
+// GetProgInfoContext is the context-aware variant of GetProgInfo.
+func (stb *SetTopBox) GetProgInfoContext(ctx context.Context, channelMajor int, channelMinor int, time int64, clientAddr string) (ProgramStatusResponse, error) {
 	var response ProgramStatusResponse
 	params := map[string]string{
 		"major": strconv.FormatInt(int64(channelMajor), 10),
@@ -348,7 +411,7 @@ func (stb *SetTopBox) GetProgInfo(channelMajor int, channelMinor int, time int64
 	if len(clientAddr) != 0 {
 		params["clientAddr"] = clientAddr
 	}
-	_, err := stb.request("/tv/getProgInfo", params, &response)
+	_, err := stb.request(ctx, "/tv/getProgInfo", params, &response)
 	if err != nil {
 		if response.Status.Message != "" {
 			err = errors.New(response.Status.Message)
@@ -361,12 +424,17 @@ func (stb *SetTopBox) GetProgInfo(channelMajor int, channelMinor int, time int64
 
 // GetTuned returns information about the program a STB is tuned to.
 func (stb *SetTopBox) GetTuned(clientAddr string) (ProgramStatusResponse, error) {
+	return stb.GetTunedContext(context.Background(), clientAddr)
+}
+
+// GetTunedContext is the context-aware variant of GetTuned.
+func (stb *SetTopBox) GetTunedContext(ctx context.Context, clientAddr string) (ProgramStatusResponse, error) {
 	var response ProgramStatusResponse
 	params := map[string]string{}
 	if len(clientAddr) != 0 {
 		params["clientAddr"] = clientAddr
 	}
-	_, err := stb.request("/tv/getTuned", params, &response)
+	_, err := stb.request(ctx, "/tv/getTuned", params, &response)
 	if err != nil {
 		if response.Status.Message != "" {
 			err = errors.New(response.Status.Message)
@@ -379,12 +447,17 @@ func (stb *SetTopBox) GetTuned(clientAddr string) (ProgramStatusResponse, error)
 
 // TuneToChannel tunes the SetTopBox to a specific channel.
 func (stb *SetTopBox) TuneToChannel(channel string, clientAddr string) error {
+	return stb.TuneToChannelContext(context.Background(), channel, clientAddr)
+}
+
+// TuneToChannelContext is the context-aware variant of TuneToChannel.
+func (stb *SetTopBox) TuneToChannelContext(ctx context.Context, channel string, clientAddr string) error {
 	var response tuneResponse
 	params := map[string]string{"major": channel}
 	if len(clientAddr) != 0 {
 		params["clientAddr"] = clientAddr
 	}
-	_, err := stb.request("/tv/tune", params, &response)
+	_, err := stb.request(ctx, "/tv/tune", params, &response)
 	if err != nil {
 		if response.Status.Message != "" {
 			err = errors.New(response.Status.Message)
@@ -395,10 +468,14 @@ func (stb *SetTopBox) TuneToChannel(channel string, clientAddr string) error {
 	return nil
 }
 
-func (stb *SetTopBox) request(uri string, params map[string]string, targetStruct interface{}) (*http.Response, error) {
+func (stb *SetTopBox) request(ctx context.Context, uri string, params map[string]string, targetStruct interface{}) (*http.Response, error) {
 	host := fmt.Sprintf("%s:%d", stb.IPAddress, stb.Port)
+	scheme := stb.scheme
+	if scheme == "" {
+		scheme = defaultScheme
+	}
 	requestURL := &url.URL{
-		Scheme: "http",
+		Scheme: scheme,
 		Host:   host,
 		Path:   uri,
 	}
@@ -409,8 +486,35 @@ func (stb *SetTopBox) request(uri string, params map[string]string, targetStruct
 	}
 	requestURL.RawQuery = values.Encode()
 
-	fmt.Println(requestURL.String())
-	res, err := http.Get(requestURL.String())
+	var res *http.Response
+	var err error
+	for attempt := 0; ; attempt++ {
+		if stb.logger != nil {
+			stb.logger.Printf("directv: requesting %s (attempt %d)", requestURL.String(), attempt+1)
+		}
+
+		var req *http.Request
+		req, err = http.NewRequestWithContext(ctx, http.MethodGet, requestURL.String(), nil)
+		if err != nil {
+			return nil, err
+		}
+		if stb.username != "" {
+			req.SetBasicAuth(stb.username, stb.password)
+		}
+
+		res, err = stb.client().Do(req)
+		if err == nil || attempt >= stb.retryCount {
+			break
+		}
+
+		timer := time.NewTimer(stb.retryBackoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return res, ctx.Err()
+		case <-timer.C:
+		}
+	}
 
 	if err != nil {
 		return res, err
@@ -426,3 +530,13 @@ func (stb *SetTopBox) request(uri string, params map[string]string, targetStruct
 
 	return res, err
 }
+
+// client returns the http.Client used for requests, falling back to
+// http.DefaultClient for a SetTopBox constructed without NewSetTopBox (e.g. a
+// bare SetTopBox{} struct literal).
+func (stb *SetTopBox) client() *http.Client {
+	if stb.httpClient != nil {
+		return stb.httpClient
+	}
+	return http.DefaultClient
+}
@@ -1,12 +1,15 @@
 package directv
 
 import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
 	"testing"
 	"time"
 )
 
 const ip = "127.0.0.1"
-const port = 8080
 const serialNum = "123456789"
 const accessCardID = "0000-0000-0000"
 const receiverID = "0000 0000 0000"
@@ -14,17 +17,50 @@ const softwareVersion = "0x994"
 const stbVersion = "1.6"
 const mode = 1
 
+// newTestSTBForServer returns a SetTopBox whose requests are sent to server,
+// so tests can substitute a mock transport instead of relying on a live
+// receiver. Additional opts are applied after WithPort/WithHTTPClient, so
+// they can override them or configure anything else a test needs.
+func newTestSTBForServer(t *testing.T, server *httptest.Server, opts ...Option) *SetTopBox {
+	t.Helper()
+
+	serverURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serverPort, err := strconv.Atoi(serverURL.Port())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stbOpts := append([]Option{WithPort(serverPort), WithHTTPClient(server.Client())}, opts...)
+	return NewSetTopBox(serverURL.Hostname(), stbOpts...)
+}
+
 func TestSTBIsConnected(t *testing.T) {
-	stb := &SetTopBox{ip, port}
-	connected, _ := stb.IsConnected()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"locations":[{"clientAddr":"0","locationName":"Living Room"}]}`))
+	}))
+	defer server.Close()
+
+	stb := newTestSTBForServer(t, server)
+	connected, err := stb.IsConnected()
+	if err != nil {
+		t.Error(err)
+	}
 	if !connected {
 		t.Error("Set Top Box is not connected.")
 	}
 }
 
 func TestSTBGetSerialNum(t *testing.T) {
-	stb := &SetTopBox{ip, port}
-	num, err := stb.GetSerialNum()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"serialNum":"` + serialNum + `"}`))
+	}))
+	defer server.Close()
+
+	stb := newTestSTBForServer(t, server)
+	num, err := stb.GetSerialNum("")
 	if err != nil {
 		t.Error(err)
 	}
@@ -34,8 +70,18 @@ func TestSTBGetSerialNum(t *testing.T) {
 }
 
 func TestSTBGetSerialNumForClient(t *testing.T) {
-	stb := &SetTopBox{ip, port}
-	num, err := stb.GetSerialNumForClient(0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("clientAddr") == "1" {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"status":{"msg":"unknown clientAddr"}}`))
+			return
+		}
+		w.Write([]byte(`{"serialNum":"` + serialNum + `"}`))
+	}))
+	defer server.Close()
+
+	stb := newTestSTBForServer(t, server)
+	num, err := stb.GetSerialNum("0")
 	if err != nil {
 		t.Error(err)
 	}
@@ -44,14 +90,20 @@ func TestSTBGetSerialNumForClient(t *testing.T) {
 	}
 
 	// Should Error
-	num, err = stb.GetSerialNumForClient(1)
+	num, err = stb.GetSerialNum("1")
 	if err == nil {
 		t.Error("Expected error for ClientAddr 1 but got num", num)
 	}
 }
 
 func TestSTBGetVersion(t *testing.T) {
-	stb := &SetTopBox{ip, port}
+	systemTime := time.Now().Unix()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"accessCardId":"` + accessCardID + `","receiverId":"` + receiverID + `","stbSoftwareVersion":"` + softwareVersion + `","systemTime":` + strconv.FormatInt(systemTime, 10) + `,"version":"` + stbVersion + `"}`))
+	}))
+	defer server.Close()
+
+	stb := newTestSTBForServer(t, server)
 	ver, err := stb.GetVersion()
 	if err != nil {
 		t.Error(err)
@@ -78,8 +130,18 @@ func TestSTBGetVersion(t *testing.T) {
 }
 
 func TestSTBGetModeForClient(t *testing.T) {
-	stb := &SetTopBox{ip, port}
-	num, err := stb.GetModeForClient(0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("clientAddr") == "1" {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"status":{"msg":"unknown clientAddr"}}`))
+			return
+		}
+		w.Write([]byte(`{"mode":` + strconv.Itoa(mode) + `}`))
+	}))
+	defer server.Close()
+
+	stb := newTestSTBForServer(t, server)
+	num, err := stb.GetMode("0")
 	if err != nil {
 		t.Error(err)
 	}
@@ -88,15 +150,20 @@ func TestSTBGetModeForClient(t *testing.T) {
 	}
 
 	// Should Error
-	num, err = stb.GetModeForClient(1)
+	num, err = stb.GetMode("1")
 	if err == nil {
 		t.Error("Expected error for ClientAddr 1 but got num", num)
 	}
 }
 
 func TestSTBGetMode(t *testing.T) {
-	stb := &SetTopBox{ip, port}
-	num, err := stb.GetMode()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"mode":` + strconv.Itoa(mode) + `}`))
+	}))
+	defer server.Close()
+
+	stb := newTestSTBForServer(t, server)
+	num, err := stb.GetMode("")
 	if err != nil {
 		t.Error(err)
 	}
@@ -105,24 +172,51 @@ func TestSTBGetMode(t *testing.T) {
 	}
 }
 
-// func TestSTBProcessKey(t *testing.T) {
-// 	stb := &SetTopBox{ip, port}
-// 	err := stb.ProcessKey("4", "keyPress")
-// 	if err != nil {
-// 		t.Error(err)
-// 	}
-// }
+func TestSTBProcessKey(t *testing.T) {
+	var gotKey, gotHold string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotKey = r.URL.Query().Get("key")
+		gotHold = r.URL.Query().Get("hold")
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
 
-// func TestSTBProcessCommand(t *testing.T) {
-// 	stb := &SetTopBox{ip, port}
-// 	_, err := stb.ProcessCommand("FA9A")
-// 	if err != nil {
-// 		t.Error(err)
-// 	}
-// }
+	stb := newTestSTBForServer(t, server)
+	err := stb.ProcessKey("4", "keyPress", "")
+	if err != nil {
+		t.Error(err)
+	}
+	if gotKey != "4" {
+		t.Error("Expected key", "4", "got", gotKey)
+	}
+	if gotHold != "keyPress" {
+		t.Error("Expected hold", "keyPress", "got", gotHold)
+	}
+}
+
+func TestSTBProcessCommand(t *testing.T) {
+	var gotCmd string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotCmd = r.URL.Query().Get("cmd")
+		w.Write([]byte(`{"return":{"data":"64","response":0,"value":0}}`))
+	}))
+	defer server.Close()
+
+	stb := newTestSTBForServer(t, server)
+	res, err := stb.ProcessCommand("FA9A")
+	if err != nil {
+		t.Error(err)
+	}
+	if gotCmd != "FA9A" {
+		t.Error("Expected cmd", "FA9A", "got", gotCmd)
+	}
+	if res.Return.Data != "64" {
+		t.Error("Expected", "64", "got", res.Return.Data)
+	}
+}
 
 // func TestSTBGetProgInfo(t *testing.T) {
-// 	stb := &SetTopBox{ip, port}
+// 	stb := NewSetTopBox(ip, WithPort(defaultPort))
 // 	res, err := stb.GetProgInfoForTime(4, 65535, time.Now().Unix())
 // 	if err != nil {
 // 		t.Error(err)
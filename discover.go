@@ -0,0 +1,245 @@
+package directv
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	ssdpAddress = "239.255.255.250:1900"
+
+	// ssdpSearchTarget is the generic UPnP device type DirecTV receivers
+	// advertise themselves under.
+	ssdpSearchTarget = "urn:schemas-upnp-org:device:MediaServer:1"
+
+	// ssdpDirecTVSearchTarget is the DirecTV-specific search target some
+	// receiver firmwares respond to in addition to the generic one above.
+	ssdpDirecTVSearchTarget = "X_com_directv_receiver"
+)
+
+// DiscoveredBox is a SetTopBox found on the network via SSDP discovery,
+// along with the identifying information from its UPnP device description.
+type DiscoveredBox struct {
+	*SetTopBox
+
+	FriendlyName string
+	ModelName    string
+	UDN          string
+	LocationURL  string
+}
+
+// upnpDeviceDescription is the subset of a UPnP device description XML
+// document that identifies a DirecTV receiver.
+type upnpDeviceDescription struct {
+	XMLName xml.Name `xml:"root"`
+	Device  struct {
+		FriendlyName string `xml:"friendlyName"`
+		ModelName    string `xml:"modelName"`
+		UDN          string `xml:"UDN"`
+	} `xml:"device"`
+}
+
+// Discover issues an SSDP M-SEARCH for DirecTV receivers on the local
+// network and returns the ones that respond before timeout elapses.
+func Discover(ctx context.Context, timeout time.Duration) ([]*DiscoveredBox, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	conn, err := net.ListenPacket("udp4", ":0")
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	addr, err := net.ResolveUDPAddr("udp4", ssdpAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, searchTarget := range []string{ssdpSearchTarget, ssdpDirecTVSearchTarget} {
+		if _, err := conn.WriteTo(ssdpSearchRequest(searchTarget), addr); err != nil {
+			return nil, err
+		}
+	}
+
+	seen := map[string]bool{}
+	var boxes []*DiscoveredBox
+
+	buf := make([]byte, 2048)
+	for {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			break
+		}
+
+		location, err := parseSSDPLocation(buf[:n])
+		if err != nil || seen[location] {
+			continue
+		}
+		seen[location] = true
+
+		box, err := fetchDiscoveredBox(ctx, location)
+		if err != nil {
+			continue
+		}
+		boxes = append(boxes, box)
+	}
+
+	return boxes, nil
+}
+
+// DiscoverContinuous listens for SSDP NOTIFY announcements until ctx is
+// cancelled, sending a DiscoveredBox to the returned channel for each
+// distinct one it observes. The channel is closed once listening stops.
+func DiscoverContinuous(ctx context.Context) <-chan DiscoveredBox {
+	out := make(chan DiscoveredBox)
+
+	go func() {
+		defer close(out)
+
+		addr, err := net.ResolveUDPAddr("udp4", ssdpAddress)
+		if err != nil {
+			return
+		}
+
+		conn, err := net.ListenMulticastUDP("udp4", nil, addr)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		go func() {
+			<-ctx.Done()
+			conn.Close()
+		}()
+
+		seen := map[string]bool{}
+		buf := make([]byte, 2048)
+		for {
+			n, _, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+
+			location, err := parseSSDPLocation(buf[:n])
+			if err != nil || seen[location] {
+				continue
+			}
+			seen[location] = true
+
+			box, err := fetchDiscoveredBox(ctx, location)
+			if err != nil {
+				continue
+			}
+
+			select {
+			case out <- *box:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+// ssdpSearchRequest builds the M-SEARCH datagram sent to the SSDP multicast
+// group for the given search target.
+func ssdpSearchRequest(searchTarget string) []byte {
+	return []byte(fmt.Sprintf(
+		"M-SEARCH * HTTP/1.1\r\n"+
+			"HOST: %s\r\n"+
+			"MAN: \"ssdp:discover\"\r\n"+
+			"MX: 2\r\n"+
+			"ST: %s\r\n\r\n",
+		ssdpAddress, searchTarget))
+}
+
+// parseSSDPLocation extracts the LOCATION header from an SSDP HTTP-over-UDP
+// response or NOTIFY message.
+func parseSSDPLocation(data []byte) (string, error) {
+	reader := textproto.NewReader(bufio.NewReader(bytes.NewReader(data)))
+
+	// The start line (HTTP/1.1 200 OK or NOTIFY * HTTP/1.1) isn't a valid
+	// MIME header, so discard it before reading the headers.
+	if _, err := reader.ReadLine(); err != nil {
+		return "", err
+	}
+
+	header, err := reader.ReadMIMEHeader()
+	if err != nil && header == nil {
+		return "", err
+	}
+
+	location := header.Get("Location")
+	if location == "" {
+		return "", fmt.Errorf("directv: no LOCATION header in SSDP response")
+	}
+
+	return location, nil
+}
+
+// fetchDiscoveredBox fetches the UPnP device description at location and
+// builds a DiscoveredBox from it.
+func fetchDiscoveredBox(ctx context.Context, location string) (*DiscoveredBox, error) {
+	locationURL, err := url.Parse(location)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var description upnpDeviceDescription
+	if err := xml.Unmarshal(body, &description); err != nil {
+		return nil, err
+	}
+
+	host := locationURL.Hostname()
+	port := defaultPort
+	if p := locationURL.Port(); p != "" {
+		if parsed, err := strconv.Atoi(p); err == nil {
+			port = parsed
+		}
+	}
+
+	return &DiscoveredBox{
+		SetTopBox:    NewSetTopBox(host, WithPort(port)),
+		FriendlyName: description.Device.FriendlyName,
+		ModelName:    description.Device.ModelName,
+		UDN:          description.Device.UDN,
+		LocationURL:  location,
+	}, nil
+}
@@ -0,0 +1,78 @@
+package directv
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParseSSDPLocation(t *testing.T) {
+	response := "HTTP/1.1 200 OK\r\n" +
+		"CACHE-CONTROL: max-age=1800\r\n" +
+		"LOCATION: http://192.168.1.50:8080/ssdp/description.xml\r\n" +
+		"ST: urn:schemas-upnp-org:device:MediaServer:1\r\n\r\n"
+
+	location, err := parseSSDPLocation([]byte(response))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if location != "http://192.168.1.50:8080/ssdp/description.xml" {
+		t.Error("Expected", "http://192.168.1.50:8080/ssdp/description.xml", "got", location)
+	}
+}
+
+func TestParseSSDPLocationMissing(t *testing.T) {
+	response := "HTTP/1.1 200 OK\r\nCACHE-CONTROL: max-age=1800\r\n\r\n"
+
+	if _, err := parseSSDPLocation([]byte(response)); err == nil {
+		t.Error("Expected an error for a response with no LOCATION header")
+	}
+}
+
+func TestFetchDiscoveredBox(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<?xml version="1.0"?>
+<root xmlns="urn:schemas-upnp-org:device-1-0">
+	<device>
+		<friendlyName>Living Room DVR</friendlyName>
+		<modelName>HR44</modelName>
+		<UDN>uuid:1234-5678</UDN>
+	</device>
+</root>`))
+	}))
+	defer server.Close()
+
+	box, err := fetchDiscoveredBox(context.Background(), server.URL+"/ssdp/description.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if box.FriendlyName != "Living Room DVR" {
+		t.Error("Expected", "Living Room DVR", "got", box.FriendlyName)
+	}
+	if box.ModelName != "HR44" {
+		t.Error("Expected", "HR44", "got", box.ModelName)
+	}
+	if box.UDN != "uuid:1234-5678" {
+		t.Error("Expected", "uuid:1234-5678", "got", box.UDN)
+	}
+	if box.LocationURL != server.URL+"/ssdp/description.xml" {
+		t.Error("Expected", server.URL+"/ssdp/description.xml", "got", box.LocationURL)
+	}
+}
+
+func TestDiscoverReturnsOnContextCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	if _, err := Discover(ctx, 5*time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	if elapsed := time.Since(start); elapsed > 1*time.Second {
+		t.Error("Expected Discover to return shortly after ctx was cancelled, took", elapsed)
+	}
+}
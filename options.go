@@ -0,0 +1,80 @@
+package directv
+
+import (
+	"net/http"
+	"time"
+)
+
+const (
+	defaultPort    = 8080
+	defaultScheme  = "http"
+	defaultTimeout = 10 * time.Second
+)
+
+// Logger is the minimal logging interface accepted by WithLogger; a
+// *log.Logger satisfies it.
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// Option configures a SetTopBox constructed via NewSetTopBox.
+type Option func(*SetTopBox)
+
+// WithPort overrides the default Set Top Box port (8080).
+func WithPort(port int) Option {
+	return func(stb *SetTopBox) {
+		stb.Port = port
+	}
+}
+
+// WithScheme overrides the default "http" scheme used to reach the Set Top
+// Box, e.g. "https" when talking to it through a TLS-terminating proxy.
+func WithScheme(scheme string) Option {
+	return func(stb *SetTopBox) {
+		stb.scheme = scheme
+	}
+}
+
+// WithHTTPClient supplies the *http.Client used for all requests, allowing
+// callers to substitute a mock transport in tests instead of relying on a
+// live Set Top Box, and to configure their own connection pooling.
+func WithHTTPClient(client *http.Client) Option {
+	return func(stb *SetTopBox) {
+		stb.httpClient = client
+	}
+}
+
+// WithTimeout sets the per-request timeout on the SetTopBox's http.Client.
+// Without it, NewSetTopBox's default client times out after 10 seconds; a
+// client supplied through WithHTTPClient keeps its own Timeout.
+func WithTimeout(timeout time.Duration) Option {
+	return func(stb *SetTopBox) {
+		stb.httpClient.Timeout = timeout
+	}
+}
+
+// WithBasicAuth configures HTTP basic auth credentials to send with every
+// request.
+func WithBasicAuth(user string, pass string) Option {
+	return func(stb *SetTopBox) {
+		stb.username = user
+		stb.password = pass
+	}
+}
+
+// WithRetry makes requests retry up to n times, sleeping backoff between
+// attempts, when the underlying HTTP round trip fails.
+func WithRetry(n int, backoff time.Duration) Option {
+	return func(stb *SetTopBox) {
+		stb.retryCount = n
+		stb.retryBackoff = backoff
+	}
+}
+
+// WithLogger attaches a Logger that SetTopBox uses to report the requests it
+// makes.
+func WithLogger(logger Logger) Option {
+	return func(stb *SetTopBox) {
+		stb.logger = logger
+	}
+}
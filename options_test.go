@@ -0,0 +1,121 @@
+package directv
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestNewSetTopBoxDefaults(t *testing.T) {
+	stb := NewSetTopBox(ip)
+	if stb.Port != defaultPort {
+		t.Error("Expected", defaultPort, "got", stb.Port)
+	}
+	if stb.scheme != defaultScheme {
+		t.Error("Expected", defaultScheme, "got", stb.scheme)
+	}
+}
+
+func TestWithHTTPClientMock(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"locations":[{"clientAddr":"0","locationName":"Living Room"}]}`))
+	}))
+	defer server.Close()
+
+	stb := newTestSTBForServer(t, server)
+
+	locations, err := stb.GetLocations()
+	if err != nil {
+		t.Error(err)
+	}
+	if len(locations) != 1 || locations[0].LocationName != "Living Room" {
+		t.Error("Expected a single Living Room location, got", locations)
+	}
+}
+
+// testLogger records the messages it was asked to log, for assertions in
+// TestWithLogger.
+type testLogger struct {
+	messages []string
+}
+
+func (l *testLogger) Printf(format string, v ...interface{}) {
+	l.messages = append(l.messages, fmt.Sprintf(format, v...))
+}
+
+func TestWithRetry(t *testing.T) {
+	var attempts int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 3 {
+			hj, ok := w.(http.Hijacker)
+			if !ok {
+				t.Fatal("ResponseWriter does not support hijacking")
+			}
+			conn, _, err := hj.Hijack()
+			if err != nil {
+				t.Fatal(err)
+			}
+			conn.Close()
+			return
+		}
+		w.Write([]byte(`{"locations":[{"clientAddr":"0","locationName":"Living Room"}]}`))
+	}))
+	defer server.Close()
+
+	stb := newTestSTBForServer(t, server, WithRetry(2, time.Millisecond))
+
+	locations, err := stb.GetLocations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if attempts != 3 {
+		t.Error("Expected 3 attempts, got", attempts)
+	}
+	if len(locations) != 1 || locations[0].LocationName != "Living Room" {
+		t.Error("Expected a single Living Room location, got", locations)
+	}
+}
+
+func TestWithBasicAuth(t *testing.T) {
+	var gotUser, gotPass string
+	var gotOK bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotUser, gotPass, gotOK = r.BasicAuth()
+		w.Write([]byte(`{"locations":[{"clientAddr":"0","locationName":"Living Room"}]}`))
+	}))
+	defer server.Close()
+
+	stb := newTestSTBForServer(t, server, WithBasicAuth("brian", "hunter2"))
+
+	if _, err := stb.GetLocations(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !gotOK {
+		t.Fatal("Expected an Authorization header, got none")
+	}
+	if gotUser != "brian" || gotPass != "hunter2" {
+		t.Error("Expected basic auth brian:hunter2, got", gotUser+":"+gotPass)
+	}
+}
+
+func TestWithLogger(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"locations":[{"clientAddr":"0","locationName":"Living Room"}]}`))
+	}))
+	defer server.Close()
+
+	logger := &testLogger{}
+	stb := newTestSTBForServer(t, server, WithLogger(logger))
+
+	if _, err := stb.GetLocations(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(logger.messages) != 1 {
+		t.Fatal("Expected exactly one logged message, got", logger.messages)
+	}
+}
@@ -0,0 +1,18 @@
+package proxy
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	requestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "directv_request_duration_seconds",
+		Help: "Duration of requests the proxy made to a backing SetTopBox.",
+	}, []string{"box", "endpoint"})
+
+	requestErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "directv_request_errors_total",
+		Help: "Count of requests the proxy made to a backing SetTopBox that returned an error.",
+	}, []string{"box", "endpoint"})
+)
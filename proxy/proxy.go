@@ -0,0 +1,291 @@
+// Package proxy fronts a fleet of directv.SetTopBox instances with a single
+// REST surface, so home-automation systems can talk to one HTTP endpoint
+// instead of N boxes.
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	directv "github.com/bsilverman/directv-remote-api-golang"
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Logger is the minimal logging interface the Server uses to report the
+// requests it proxies; *log.Logger satisfies it.
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// Option configures a Server constructed via NewServer.
+type Option func(*Server)
+
+// WithLogger attaches a Logger the Server uses to report proxied requests.
+func WithLogger(logger Logger) Option {
+	return func(s *Server) {
+		s.logger = logger
+	}
+}
+
+// WithBroadcastConcurrency bounds how many boxes POST /broadcast/key/{key}
+// fans out to at once. The default is 8.
+func WithBroadcastConcurrency(n int) Option {
+	return func(s *Server) {
+		s.broadcastConcurrency = n
+	}
+}
+
+// managedBox pairs a SetTopBox with a mutex that serializes the key and tune
+// requests sent to one box. The lock is held for a single HTTP request, which
+// sends a single key, so a multi-key sequence spread over several requests
+// (e.g. a multi-digit channel entry) can still be interleaved with other
+// callers' keys.
+type managedBox struct {
+	stb *directv.SetTopBox
+	mu  sync.Mutex
+}
+
+// Server is an http.Handler (via Handler) that fronts a fleet of
+// directv.SetTopBox instances behind a stable REST API.
+type Server struct {
+	Addr string
+
+	logger               Logger
+	broadcastConcurrency int
+
+	mu    sync.RWMutex
+	boxes map[string]*managedBox
+
+	httpServer *http.Server
+}
+
+// NewServer creates a Server listening on addr once ListenAndServe is
+// called. Boxes must be registered with Register (or RegisterDiscovered)
+// before they're reachable through the REST API.
+func NewServer(addr string, opts ...Option) *Server {
+	s := &Server{
+		Addr:                 addr,
+		broadcastConcurrency: 8,
+		boxes:                map[string]*managedBox{},
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// Register adds a SetTopBox to the fleet under id, replacing any box
+// previously registered under the same id.
+func (s *Server) Register(id string, stb *directv.SetTopBox) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.boxes[id] = &managedBox{stb: stb}
+}
+
+// RegisterDiscovered registers each discovered box under its UDN.
+func (s *Server) RegisterDiscovered(found []*directv.DiscoveredBox) {
+	for _, box := range found {
+		s.Register(box.UDN, box.SetTopBox)
+	}
+}
+
+// Deregister removes a SetTopBox from the fleet.
+func (s *Server) Deregister(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.boxes, id)
+}
+
+// Handler returns the http.Handler implementing the proxy's REST API.
+func (s *Server) Handler() http.Handler {
+	router := mux.NewRouter()
+	router.HandleFunc("/boxes", s.handleListBoxes).Methods(http.MethodGet)
+	router.HandleFunc("/boxes/{id}/tuned", s.handleGetTuned).Methods(http.MethodGet)
+	router.HandleFunc("/boxes/{id}/key/{key}", s.handleProcessKey).Methods(http.MethodPost)
+	router.HandleFunc("/boxes/{id}/tune/{chan}", s.handleTuneToChannel).Methods(http.MethodPost)
+	router.HandleFunc("/broadcast/key/{key}", s.handleBroadcastKey).Methods(http.MethodPost)
+	router.Handle("/metrics", promhttp.Handler()).Methods(http.MethodGet)
+	return router
+}
+
+// ListenAndServe starts serving the proxy's REST API on s.Addr. It blocks
+// until the server stops, and always returns a non-nil error, as
+// http.Server.ListenAndServe does.
+func (s *Server) ListenAndServe() error {
+	s.httpServer = &http.Server{Addr: s.Addr, Handler: s.Handler()}
+	return s.httpServer.ListenAndServe()
+}
+
+// Shutdown gracefully stops a Server started with ListenAndServe.
+func (s *Server) Shutdown(ctx context.Context) error {
+	if s.httpServer == nil {
+		return nil
+	}
+	return s.httpServer.Shutdown(ctx)
+}
+
+func (s *Server) box(id string) (*managedBox, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	box, ok := s.boxes[id]
+	return box, ok
+}
+
+func (s *Server) handleListBoxes(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	ids := make([]string, 0, len(s.boxes))
+	for id := range s.boxes {
+		ids = append(ids, id)
+	}
+	s.mu.RUnlock()
+
+	writeJSON(w, http.StatusOK, ids)
+}
+
+func (s *Server) handleGetTuned(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	box, ok := s.box(id)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	s.track(id, "getTuned", func() error {
+		program, err := box.stb.GetTunedContext(r.Context(), r.URL.Query().Get("clientAddr"))
+		if err != nil {
+			return err
+		}
+		writeJSON(w, http.StatusOK, program)
+		return nil
+	}, w)
+}
+
+func (s *Server) handleProcessKey(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, key := vars["id"], vars["key"]
+	box, ok := s.box(id)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	box.mu.Lock()
+	defer box.mu.Unlock()
+
+	s.track(id, "processKey", func() error {
+		if err := box.stb.ProcessKeyContext(r.Context(), key, r.URL.Query().Get("hold"), r.URL.Query().Get("clientAddr")); err != nil {
+			return err
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}, w)
+}
+
+func (s *Server) handleTuneToChannel(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, channel := vars["id"], vars["chan"]
+	box, ok := s.box(id)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	box.mu.Lock()
+	defer box.mu.Unlock()
+
+	s.track(id, "tune", func() error {
+		if err := box.stb.TuneToChannelContext(r.Context(), channel, r.URL.Query().Get("clientAddr")); err != nil {
+			return err
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}, w)
+}
+
+// handleBroadcastKey fans a key press out to every registered box, bounded
+// by broadcastConcurrency, and reports per-box success/failure.
+func (s *Server) handleBroadcastKey(w http.ResponseWriter, r *http.Request) {
+	key := mux.Vars(r)["key"]
+	hold := r.URL.Query().Get("hold")
+	clientAddr := r.URL.Query().Get("clientAddr")
+
+	s.mu.RLock()
+	targets := make(map[string]*managedBox, len(s.boxes))
+	for id, box := range s.boxes {
+		targets[id] = box
+	}
+	s.mu.RUnlock()
+
+	results := make(map[string]string, len(targets))
+	var resultsMu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, s.broadcastConcurrency)
+
+	for id, box := range targets {
+		id, box := id, box
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			box.mu.Lock()
+			defer box.mu.Unlock()
+
+			status := "ok"
+			err := s.measure(id, "broadcastKey", func() error {
+				return box.stb.ProcessKeyContext(r.Context(), key, hold, clientAddr)
+			})
+			if err != nil {
+				status = err.Error()
+			}
+
+			resultsMu.Lock()
+			results[id] = status
+			resultsMu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	writeJSON(w, http.StatusOK, results)
+}
+
+// track runs fn, recording metrics and logs for box/endpoint, and writes an
+// error response if fn fails.
+func (s *Server) track(box string, endpoint string, fn func() error, w http.ResponseWriter) {
+	err := s.measure(box, endpoint, fn)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+	}
+}
+
+// measure runs fn, recording its duration and any error via Prometheus
+// metrics and the configured Logger.
+func (s *Server) measure(box string, endpoint string, fn func() error) error {
+	start := time.Now()
+	err := fn()
+	duration := time.Since(start)
+
+	requestDuration.WithLabelValues(box, endpoint).Observe(duration.Seconds())
+	if err != nil {
+		requestErrors.WithLabelValues(box, endpoint).Inc()
+	}
+
+	if s.logger != nil {
+		s.logger.Printf("directv/proxy: box=%s endpoint=%s duration=%s err=%v", box, endpoint, duration, err)
+	}
+
+	return err
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
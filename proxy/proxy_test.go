@@ -0,0 +1,124 @@
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	directv "github.com/bsilverman/directv-remote-api-golang"
+)
+
+func newTestBox(t *testing.T, handler http.HandlerFunc) *directv.SetTopBox {
+	t.Helper()
+
+	stbServer := httptest.NewServer(handler)
+	t.Cleanup(stbServer.Close)
+
+	stbURL, err := url.Parse(stbServer.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	port, err := strconv.Atoi(stbURL.Port())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return directv.NewSetTopBox(stbURL.Hostname(), directv.WithPort(port), directv.WithHTTPClient(stbServer.Client()))
+}
+
+func TestHandleListBoxes(t *testing.T) {
+	server := NewServer(":0")
+	server.Register("living-room", newTestBox(t, nil))
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/boxes", nil)
+	server.Handler().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatal("Expected", http.StatusOK, "got", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), "living-room") {
+		t.Error("Expected body to contain", "living-room", "got", rec.Body.String())
+	}
+}
+
+func TestHandleGetTuned(t *testing.T) {
+	box := newTestBox(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/tv/getTuned" {
+			w.Write([]byte(`{"title":"Let's Make a Deal"}`))
+		}
+	})
+
+	server := NewServer(":0")
+	server.Register("living-room", box)
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/boxes/living-room/tuned", nil)
+	server.Handler().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatal("Expected", http.StatusOK, "got", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), "Let's Make a Deal") {
+		t.Error("Expected body to contain program title, got", rec.Body.String())
+	}
+}
+
+func TestHandleGetTunedUnknownBox(t *testing.T) {
+	server := NewServer(":0")
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/boxes/unknown/tuned", nil)
+	server.Handler().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Error("Expected", http.StatusNotFound, "got", rec.Code)
+	}
+}
+
+func TestHandleProcessKey(t *testing.T) {
+	var gotKey string
+	box := newTestBox(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/remote/processKey" {
+			gotKey = r.URL.Query().Get("key")
+			w.Write([]byte(`{}`))
+		}
+	})
+
+	server := NewServer(":0")
+	server.Register("living-room", box)
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/boxes/living-room/key/"+directv.Key5, nil)
+	server.Handler().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNoContent {
+		t.Fatal("Expected", http.StatusNoContent, "got", rec.Code)
+	}
+	if gotKey != directv.Key5 {
+		t.Error("Expected key", directv.Key5, "got", gotKey)
+	}
+}
+
+func TestHandleBroadcastKey(t *testing.T) {
+	boxA := newTestBox(t, func(w http.ResponseWriter, r *http.Request) { w.Write([]byte(`{}`)) })
+	boxB := newTestBox(t, func(w http.ResponseWriter, r *http.Request) { w.Write([]byte(`{}`)) })
+
+	server := NewServer(":0")
+	server.Register("a", boxA)
+	server.Register("b", boxB)
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/broadcast/key/"+directv.KeyGuide, nil)
+	server.Handler().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatal("Expected", http.StatusOK, "got", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), `"a":"ok"`) || !strings.Contains(rec.Body.String(), `"b":"ok"`) {
+		t.Error("Expected both boxes to report ok, got", rec.Body.String())
+	}
+}
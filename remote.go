@@ -0,0 +1,126 @@
+package directv
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// Channel is a decoded major/minor channel pair, as returned by
+// CommandGetCurrentChannel.
+type Channel struct {
+	Major int
+	Minor int
+}
+
+// Step is a single action in a Macro: a key press, optionally held, with a
+// delay before the next Step runs.
+type Step struct {
+	Key   string
+	Hold  string
+	Delay time.Duration
+}
+
+// TypeChannel decomposes channel (e.g. "509") into the individual digit key
+// presses followed by KeyEnter, waiting delay between each press.
+func (stb *SetTopBox) TypeChannel(ctx context.Context, channel string, clientAddr string, delay time.Duration) error {
+	steps := make([]Step, 0, len(channel)+1)
+	for _, digit := range channel {
+		if digit < '0' || digit > '9' {
+			return fmt.Errorf("directv: invalid channel digit %q", digit)
+		}
+		steps = append(steps, Step{Key: string(digit), Hold: HoldPressAndRelease, Delay: delay})
+	}
+	steps = append(steps, Step{Key: KeyEnter, Hold: HoldPressAndRelease})
+
+	return stb.Macro(ctx, steps, clientAddr)
+}
+
+// Macro runs a scripted sequence of key presses, such as a record-then-tune-
+// then-menu sequence, waiting each Step's Delay before sending the next one.
+func (stb *SetTopBox) Macro(ctx context.Context, steps []Step, clientAddr string) error {
+	for i, step := range steps {
+		if err := stb.ProcessKeyContext(ctx, step.Key, step.Hold, clientAddr); err != nil {
+			return err
+		}
+
+		if i == len(steps)-1 || step.Delay == 0 {
+			continue
+		}
+
+		select {
+		case <-time.After(step.Delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return nil
+}
+
+// GetSignalQualityDecoded calls CommandGetSignalQuality and decodes the
+// result into a 0-100 signal quality value.
+func (stb *SetTopBox) GetSignalQualityDecoded() (int, error) {
+	return stb.GetSignalQualityDecodedContext(context.Background())
+}
+
+// GetSignalQualityDecodedContext is the context-aware variant of
+// GetSignalQualityDecoded.
+func (stb *SetTopBox) GetSignalQualityDecodedContext(ctx context.Context) (int, error) {
+	response, err := stb.ProcessCommandContext(ctx, CommandGetSignalQuality)
+	if err != nil {
+		return 0, err
+	}
+	return decodeSignalQuality(response.Return.Data)
+}
+
+// GetCurrentChannelDecoded calls CommandGetCurrentChannel and decodes the
+// result into a Channel.
+func (stb *SetTopBox) GetCurrentChannelDecoded() (Channel, error) {
+	return stb.GetCurrentChannelDecodedContext(context.Background())
+}
+
+// GetCurrentChannelDecodedContext is the context-aware variant of
+// GetCurrentChannelDecoded.
+func (stb *SetTopBox) GetCurrentChannelDecodedContext(ctx context.Context) (Channel, error) {
+	response, err := stb.ProcessCommandContext(ctx, CommandGetCurrentChannel)
+	if err != nil {
+		return Channel{}, err
+	}
+	return decodeChannel(response.Return.Data)
+}
+
+// GetCurrentTimeDecoded calls CommandGetCurrentTime and decodes the result
+// into a time.Time.
+func (stb *SetTopBox) GetCurrentTimeDecoded() (time.Time, error) {
+	return stb.GetCurrentTimeDecodedContext(context.Background())
+}
+
+// GetCurrentTimeDecodedContext is the context-aware variant of
+// GetCurrentTimeDecoded.
+func (stb *SetTopBox) GetCurrentTimeDecodedContext(ctx context.Context) (time.Time, error) {
+	response, err := stb.ProcessCommandContext(ctx, CommandGetCurrentTime)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return decodeBCDTime(response.Return.Data)
+}
+
+// decodeChannel parses the 4-byte major/minor channel pair returned by
+// CommandGetCurrentChannel: 2 big-endian bytes of major channel followed by
+// 2 big-endian bytes of minor channel.
+func decodeChannel(data string) (Channel, error) {
+	raw, err := hex.DecodeString(data)
+	if err != nil {
+		return Channel{}, err
+	}
+	if len(raw) < 4 {
+		return Channel{}, fmt.Errorf("directv: expected 4 channel bytes, got %d", len(raw))
+	}
+
+	return Channel{
+		Major: int(raw[0])<<8 | int(raw[1]),
+		Minor: int(raw[2])<<8 | int(raw[3]),
+	}, nil
+}
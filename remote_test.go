@@ -0,0 +1,82 @@
+package directv
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestDecodeChannel(t *testing.T) {
+	channel, err := decodeChannel("01FD0000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if channel.Major != 509 || channel.Minor != 0 {
+		t.Error("Expected major 509 minor 0, got", channel)
+	}
+}
+
+func TestTypeChannel(t *testing.T) {
+	var keys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.URL.Query().Get("key"))
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	stb := newTestSTBForServer(t, server)
+	if err := stb.TypeChannel(context.Background(), "509", "", 0); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{Key5, Key0, Key9, KeyEnter}
+	if len(keys) != len(expected) {
+		t.Fatalf("Expected %d key presses, got %d: %v", len(expected), len(keys), keys)
+	}
+	for i, key := range expected {
+		if keys[i] != key {
+			t.Error("Expected key", key, "at position", i, "got", keys[i])
+		}
+	}
+}
+
+func TestMacro(t *testing.T) {
+	var keys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.URL.Query().Get("key"))
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	stb := newTestSTBForServer(t, server)
+
+	steps := []Step{
+		{Key: KeyRecord, Hold: HoldPressAndRelease},
+		{Key: KeyGuide, Hold: HoldPressAndRelease, Delay: time.Millisecond},
+	}
+	if err := stb.Macro(context.Background(), steps, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(keys) != 2 || keys[0] != KeyRecord || keys[1] != KeyGuide {
+		t.Error("Expected", []string{KeyRecord, KeyGuide}, "got", keys)
+	}
+}
+
+func TestGetSignalQualityDecoded(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"return":{"data":"5A","response":0,"value":0}}`))
+	}))
+	defer server.Close()
+
+	stb := newTestSTBForServer(t, server)
+	quality, err := stb.GetSignalQualityDecoded()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if quality != 90 {
+		t.Error("Expected", 90, "got", quality)
+	}
+}
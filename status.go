@@ -0,0 +1,238 @@
+package directv
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+	"gopkg.in/yaml.v2"
+)
+
+// PrimaryStatus is the decoded flag byte returned by the
+// CommandGetPrimaryStatus (FA83) serial command.
+type PrimaryStatus struct {
+	Raw       byte `json:"raw" yaml:"raw"`
+	Active    bool `json:"active" yaml:"active"`
+	Recording bool `json:"recording" yaml:"recording"`
+	VOD       bool `json:"vod" yaml:"vod"`
+}
+
+// Status is a point-in-time diagnostic snapshot of a SetTopBox, aggregating
+// connectivity, version, tuner, and signal information that would otherwise
+// require several separate calls.
+type Status struct {
+	Reachable      bool                   `json:"reachable" yaml:"reachable"`
+	LatencyMs      int64                  `json:"latencyMs" yaml:"latencyMs"`
+	Version        Version                `json:"version" yaml:"version"`
+	Mode           int                    `json:"mode" yaml:"mode"`
+	CurrentProgram *ProgramStatusResponse `json:"currentProgram,omitempty" yaml:"currentProgram,omitempty"`
+	SignalQuality  int                    `json:"signalQuality" yaml:"signalQuality"`
+	PrimaryStatus  PrimaryStatus          `json:"primaryStatus" yaml:"primaryStatus"`
+	ReceiverTime   time.Time              `json:"receiverTime" yaml:"receiverTime"`
+
+	// Uptime is left zero; the SHEF serial protocol DirecTV receivers
+	// expose has no command that reports receiver uptime.
+	Uptime time.Duration `json:"uptime" yaml:"uptime"`
+}
+
+// JSON marshals the Status as JSON, for piping to monitoring systems.
+func (s Status) JSON() ([]byte, error) {
+	return json.Marshal(s)
+}
+
+// YAML marshals the Status as YAML, for piping to monitoring systems.
+func (s Status) YAML() ([]byte, error) {
+	return yaml.Marshal(s)
+}
+
+// Status fans out to IsConnected, GetVersion, GetMode, GetTuned, and the
+// signal quality, current time, and primary status serial commands
+// concurrently, decoding each into a single diagnostic snapshot.
+func (stb *SetTopBox) Status(ctx context.Context, clientAddr string) (Status, error) {
+	var status Status
+
+	start := time.Now()
+	g, gctx := errgroup.WithContext(ctx)
+
+	g.Go(func() error {
+		reachable, err := stb.IsConnectedContext(gctx)
+		status.Reachable = reachable
+		status.LatencyMs = time.Since(start).Milliseconds()
+		return err
+	})
+
+	g.Go(func() error {
+		version, err := stb.GetVersionContext(gctx)
+		if err != nil {
+			return err
+		}
+		status.Version = version
+		return nil
+	})
+
+	g.Go(func() error {
+		mode, err := stb.GetModeContext(gctx, clientAddr)
+		if err != nil {
+			return err
+		}
+		status.Mode = mode
+		return nil
+	})
+
+	g.Go(func() error {
+		program, err := stb.GetTunedContext(gctx, clientAddr)
+		if err != nil {
+			return err
+		}
+		status.CurrentProgram = &program
+		return nil
+	})
+
+	g.Go(func() error {
+		response, err := stb.ProcessCommandContext(gctx, CommandGetSignalQuality)
+		if err != nil {
+			return err
+		}
+		quality, err := decodeSignalQuality(response.Return.Data)
+		if err != nil {
+			return err
+		}
+		status.SignalQuality = quality
+		return nil
+	})
+
+	g.Go(func() error {
+		response, err := stb.ProcessCommandContext(gctx, CommandGetCurrentTime)
+		if err != nil {
+			return err
+		}
+		receiverTime, err := decodeBCDTime(response.Return.Data)
+		if err != nil {
+			return err
+		}
+		status.ReceiverTime = receiverTime
+		return nil
+	})
+
+	g.Go(func() error {
+		response, err := stb.ProcessCommandContext(gctx, CommandGetPrimaryStatus)
+		if err != nil {
+			return err
+		}
+		primaryStatus, err := decodePrimaryStatus(response.Return.Data)
+		if err != nil {
+			return err
+		}
+		status.PrimaryStatus = primaryStatus
+		return nil
+	})
+
+	if err := g.Wait(); err != nil {
+		return status, err
+	}
+
+	return status, nil
+}
+
+// StatusStream calls Status on a timer every interval until ctx is
+// cancelled, sending each successful result to the returned channel; a call
+// that returns an error sends nothing. The channel is closed once the stream
+// stops.
+func (stb *SetTopBox) StatusStream(ctx context.Context, clientAddr string, interval time.Duration) <-chan Status {
+	out := make(chan Status)
+
+	go func() {
+		defer close(out)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			status, err := stb.Status(ctx, clientAddr)
+			if err == nil {
+				select {
+				case out <- status:
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+// decodeSignalQuality parses the single-byte signal quality (0-100)
+// returned by CommandGetSignalQuality.
+func decodeSignalQuality(data string) (int, error) {
+	raw, err := decodeHexByte(data)
+	if err != nil {
+		return 0, err
+	}
+	return int(raw), nil
+}
+
+// decodePrimaryStatus parses the single flag byte returned by
+// CommandGetPrimaryStatus.
+func decodePrimaryStatus(data string) (PrimaryStatus, error) {
+	raw, err := decodeHexByte(data)
+	if err != nil {
+		return PrimaryStatus{}, err
+	}
+
+	return PrimaryStatus{
+		Raw:       raw,
+		Active:    raw&0x01 != 0,
+		Recording: raw&0x02 != 0,
+		VOD:       raw&0x04 != 0,
+	}, nil
+}
+
+// decodeBCDTime parses the 6-byte BCD-encoded year/month/day/hour/minute/
+// second returned by CommandGetCurrentTime.
+func decodeBCDTime(data string) (time.Time, error) {
+	raw, err := hex.DecodeString(data)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if len(raw) < 6 {
+		return time.Time{}, fmt.Errorf("directv: expected 6 BCD-encoded time bytes, got %d", len(raw))
+	}
+
+	year := 2000 + bcdByteToInt(raw[0])
+	month := bcdByteToInt(raw[1])
+	day := bcdByteToInt(raw[2])
+	hour := bcdByteToInt(raw[3])
+	minute := bcdByteToInt(raw[4])
+	second := bcdByteToInt(raw[5])
+
+	return time.Date(year, time.Month(month), day, hour, minute, second, 0, time.UTC), nil
+}
+
+// decodeHexByte decodes the first byte of a hex-encoded serial command data
+// field.
+func decodeHexByte(data string) (byte, error) {
+	raw, err := hex.DecodeString(data)
+	if err != nil {
+		return 0, err
+	}
+	if len(raw) < 1 {
+		return 0, fmt.Errorf("directv: expected at least one byte of serial data")
+	}
+	return raw[0], nil
+}
+
+// bcdByteToInt decodes a single binary-coded-decimal byte into its integer
+// value, e.g. 0x34 -> 34.
+func bcdByteToInt(b byte) int {
+	return int(b>>4)*10 + int(b&0x0F)
+}
@@ -0,0 +1,109 @@
+package directv
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestDecodeSignalQuality(t *testing.T) {
+	quality, err := decodeSignalQuality("5A")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if quality != 90 {
+		t.Error("Expected", 90, "got", quality)
+	}
+}
+
+func TestDecodePrimaryStatus(t *testing.T) {
+	status, err := decodePrimaryStatus("03")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !status.Active || !status.Recording || status.VOD {
+		t.Error("Expected Active and Recording but not VOD, got", status)
+	}
+}
+
+func TestDecodeBCDTime(t *testing.T) {
+	decoded, err := decodeBCDTime("260712153045")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := time.Date(2026, time.July, 12, 15, 30, 45, 0, time.UTC)
+	if !decoded.Equal(expected) {
+		t.Error("Expected", expected, "got", decoded)
+	}
+}
+
+func TestStatusJSONAndYAML(t *testing.T) {
+	status := Status{Reachable: true, SignalQuality: 80}
+
+	js, err := status.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var roundTripped Status
+	if err := json.Unmarshal(js, &roundTripped); err != nil {
+		t.Fatal(err)
+	}
+	if roundTripped.SignalQuality != 80 {
+		t.Error("Expected", 80, "got", roundTripped.SignalQuality)
+	}
+
+	if _, err := status.YAML(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSTBStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/info/getLocations":
+			w.Write([]byte(`{"locations":[{"clientAddr":"0","locationName":"Living Room"}]}`))
+		case "/info/getVersion":
+			w.Write([]byte(`{"accessCardId":"` + accessCardID + `","receiverId":"` + receiverID + `","stbSoftwareVersion":"` + softwareVersion + `","version":"` + stbVersion + `"}`))
+		case "/info/mode":
+			w.Write([]byte(`{"mode":1}`))
+		case "/tv/getTuned":
+			w.Write([]byte(`{"title":"Let's Make a Deal"}`))
+		case "/serial/processCommand":
+			switch r.URL.Query().Get("cmd") {
+			case CommandGetSignalQuality:
+				w.Write([]byte(`{"command":true,"param":true,"prefix":true,"return":{"data":"5A","response":0,"value":0}}`))
+			case CommandGetCurrentTime:
+				w.Write([]byte(`{"command":true,"param":true,"prefix":true,"return":{"data":"260712153045","response":0,"value":0}}`))
+			case CommandGetPrimaryStatus:
+				w.Write([]byte(`{"command":true,"param":true,"prefix":true,"return":{"data":"03","response":0,"value":0}}`))
+			}
+		}
+	}))
+	defer server.Close()
+
+	stb := newTestSTBForServer(t, server)
+
+	status, err := stb.Status(context.Background(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !status.Reachable {
+		t.Error("Expected Reachable to be true")
+	}
+	if status.SignalQuality != 90 {
+		t.Error("Expected", 90, "got", status.SignalQuality)
+	}
+	if status.Mode != 1 {
+		t.Error("Expected", 1, "got", status.Mode)
+	}
+	if status.CurrentProgram == nil || status.CurrentProgram.Title != "Let's Make a Deal" {
+		t.Error("Expected CurrentProgram title", "Let's Make a Deal", "got", status.CurrentProgram)
+	}
+	if !status.PrimaryStatus.Active || !status.PrimaryStatus.Recording {
+		t.Error("Expected Active and Recording primary status, got", status.PrimaryStatus)
+	}
+}